@@ -1,10 +1,5 @@
 package gaio
 
-import (
-	"net"
-	"syscall"
-)
-
 // poller wait max events count
 const maxEvents = 1024
 
@@ -19,28 +14,3 @@ type event struct {
 // and batch processing is the key to amortize context switching costs for
 // tiny messages.
 type pollerEvents []event
-
-// dupconn use RawConn to dup() file descriptor
-func dupconn(conn net.Conn) (newfd int, err error) {
-	sc, ok := conn.(interface {
-		SyscallConn() (syscall.RawConn, error)
-	})
-	if !ok {
-		return -1, ErrUnsupported
-	}
-	rc, err := sc.SyscallConn()
-	if err != nil {
-		return -1, ErrUnsupported
-	}
-
-	// Control() gurantees the integrity of file descriptor
-	ec := rc.Control(func(fd uintptr) {
-		newfd, err = syscall.Dup(int(fd))
-	})
-
-	if ec != nil {
-		return -1, ec
-	}
-
-	return
-}
@@ -0,0 +1,103 @@
+//go:build darwin || freebsd || dragonfly || netbsd || openbsd
+
+package gaio
+
+import "syscall"
+
+// poller drives a single kqueue(2) instance with one read and one write
+// filter registered per fd, the BSD-family counterpart to aio_linux.go's
+// epoll backend, plugging into the same chEventNotify/Watch/Free/Close
+// surface the rest of the watcher is written against.
+type poller struct {
+	fd int
+}
+
+// openPoll creates the kqueue instance backing this watcher.
+func openPoll() (*poller, error) {
+	fd, err := syscall.Kqueue()
+	if err != nil {
+		return nil, err
+	}
+	return &poller{fd: fd}, nil
+}
+
+// Watch arms 'fd' for both read and write readiness via EVFILT_READ and
+// EVFILT_WRITE, identifying events by 'fd' itself (Ident) in Wait().
+func (p *poller) Watch(fd int) error {
+	changes := []syscall.Kevent_t{
+		{Ident: uint64(fd), Filter: syscall.EVFILT_READ, Flags: syscall.EV_ADD | syscall.EV_CLEAR},
+		{Ident: uint64(fd), Filter: syscall.EVFILT_WRITE, Flags: syscall.EV_ADD | syscall.EV_CLEAR},
+	}
+	_, err := syscall.Kevent(p.fd, changes, nil, nil)
+	return err
+}
+
+// Wait blocks on kevent and converts readiness notifications into the
+// event{ident, r, w} shape expected by watcher.loop().
+func (p *poller) Wait(chEventNotify chan pollerEvents, die chan struct{}) {
+	events := make([]syscall.Kevent_t, maxEvents)
+	for {
+		n, err := syscall.Kevent(p.fd, nil, events, nil)
+		if err != nil {
+			if err == syscall.EINTR {
+				continue
+			}
+			select {
+			case <-die:
+				return
+			default:
+				continue
+			}
+		}
+
+		pe := make(pollerEvents, 0, n)
+		for i := 0; i < n; i++ {
+			e := events[i]
+			ev := event{ident: int(e.Ident)}
+			switch e.Filter {
+			case syscall.EVFILT_READ:
+				ev.r = true
+			case syscall.EVFILT_WRITE:
+				ev.w = true
+			}
+			if e.Flags&syscall.EV_EOF != 0 {
+				ev.r = true
+				ev.w = true
+			}
+			pe = append(pe, ev)
+		}
+
+		if len(pe) == 0 {
+			continue
+		}
+
+		select {
+		case chEventNotify <- pe:
+		case <-die:
+			return
+		}
+	}
+}
+
+// Free removes both filters for 'fd', matching how the windows backend's
+// Free() cancels the outstanding AFD poll IOSB -- without it, a reused fd
+// value after close() would keep delivering events tied to its old conn.
+func (p *poller) Free(fd int) error {
+	changes := []syscall.Kevent_t{
+		{Ident: uint64(fd), Filter: syscall.EVFILT_READ, Flags: syscall.EV_DELETE},
+		{Ident: uint64(fd), Filter: syscall.EVFILT_WRITE, Flags: syscall.EV_DELETE},
+	}
+	// the kernel drops both filters automatically on close(2); this merely
+	// makes it explicit ahead of sysClose(fd) and tolerates the fd already
+	// being gone.
+	_, err := syscall.Kevent(p.fd, changes, nil, nil)
+	if err != nil && err != syscall.ENOENT && err != syscall.EBADF {
+		return err
+	}
+	return nil
+}
+
+// Close tears down the kqueue instance.
+func (p *poller) Close() error {
+	return syscall.Close(p.fd)
+}
@@ -0,0 +1,72 @@
+//go:build !windows
+
+package gaio
+
+import (
+	"net"
+	"syscall"
+	"unsafe"
+)
+
+// dupconn use RawConn to dup() file descriptor
+func dupconn(conn net.Conn) (newfd int, err error) {
+	sc, ok := conn.(interface {
+		SyscallConn() (syscall.RawConn, error)
+	})
+	if !ok {
+		return -1, ErrUnsupported
+	}
+	rc, err := sc.SyscallConn()
+	if err != nil {
+		return -1, ErrUnsupported
+	}
+
+	// Control() gurantees the integrity of file descriptor
+	ec := rc.Control(func(fd uintptr) {
+		newfd, err = syscall.Dup(int(fd))
+	})
+
+	if ec != nil {
+		return -1, ec
+	}
+
+	return
+}
+
+// sysRead, sysWrite and sysClose give watcher.go a plain-int-fd surface to
+// call through; on unix 'fd' already is the native descriptor type.
+func sysRead(fd int, buf []byte) (int, error)  { return syscall.Read(fd, buf) }
+func sysWrite(fd int, buf []byte) (int, error) { return syscall.Write(fd, buf) }
+func sysClose(fd int) error                    { return syscall.Close(fd) }
+
+// sysReadv and sysWritev hand-roll readv(2)/writev(2) via syscall.Syscall:
+// the standard syscall package exposes syscall.Iovec and the SYS_READV/
+// SYS_WRITEV trap numbers but, unlike golang.org/x/sys/unix, no Readv/Writev
+// wrapper of its own, and this module carries no external dependencies.
+func sysReadv(fd int, iovs [][]byte) (int, error) {
+	return vectorIO(syscall.SYS_READV, fd, iovs)
+}
+
+func sysWritev(fd int, iovs [][]byte) (int, error) {
+	return vectorIO(syscall.SYS_WRITEV, fd, iovs)
+}
+
+func vectorIO(trap uintptr, fd int, iovs [][]byte) (int, error) {
+	if len(iovs) == 0 {
+		return 0, nil
+	}
+
+	iovecs := make([]syscall.Iovec, len(iovs))
+	for i, b := range iovs {
+		if len(b) > 0 {
+			iovecs[i].Base = &b[0]
+		}
+		iovecs[i].SetLen(len(b))
+	}
+
+	r0, _, errno := syscall.Syscall(trap, uintptr(fd), uintptr(unsafe.Pointer(&iovecs[0])), uintptr(len(iovecs)))
+	if errno != 0 {
+		return int(r0), errno
+	}
+	return int(r0), nil
+}
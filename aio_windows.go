@@ -0,0 +1,386 @@
+//go:build windows
+
+package gaio
+
+import (
+	"net"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// this implementation drives a single per-process I/O Completion Port and
+// issues AFD.sys POLL requests to obtain *readiness* (not completion) events
+// for arbitrary SOCKET handles, following the approach used by mio/tokio on
+// windows. it lets the rest of the watcher, written against a readiness
+// model, run unmodified on windows.
+
+var (
+	modntdll                        = syscall.NewLazyDLL("ntdll.dll")
+	procNtDeviceIoControlFile       = modntdll.NewProc("NtDeviceIoControlFile")
+	procNtCancelIoFileEx            = modntdll.NewProc("NtCancelIoFileEx")
+	procNtCreateFile                = modntdll.NewProc("NtCreateFile")
+	modkernel32                     = syscall.NewLazyDLL("kernel32.dll")
+	procCreateIoCompletionPort      = modkernel32.NewProc("CreateIoCompletionPort")
+	procGetQueuedCompletionStatusEx = modkernel32.NewProc("GetQueuedCompletionStatusEx")
+)
+
+// IOCTL_AFD_POLL is the device control code AFD.sys exposes for readiness polling.
+const ioctlAfdPoll = 0x00012024
+
+// AFD poll bitmask, as consumed/produced by \Device\Afd.
+const (
+	afdPollReceive     = 0x0001
+	afdPollSend        = 0x0004
+	afdPollDisconnect  = 0x0008
+	afdPollAbort       = 0x0010
+	afdPollLocalClose  = 0x0020
+	afdPollConnect     = 0x0002
+	afdPollConnectFail = 0x0080
+)
+
+// ioStatusBlock mirrors the kernel IO_STATUS_BLOCK structure.
+type ioStatusBlock struct {
+	status      uintptr
+	information uintptr
+}
+
+// afdPollHandleInfo mirrors AFD_HANDLE poll entries embedded in AFD_POLL_INFO.
+type afdPollHandleInfo struct {
+	handle syscall.Handle
+	events uint32
+	status uint32
+}
+
+// afdPollInfo mirrors AFD_POLL_INFO, a single-handle variant is sufficient
+// since every socket gets its own dedicated poll request here.
+type afdPollInfo struct {
+	timeout    int64
+	numHandles uint32
+	exclusive  uint32
+	handles    [1]afdPollHandleInfo
+}
+
+// pollReq tracks one outstanding AFD poll IOSB for a watched fd.
+type pollReq struct {
+	iosb  ioStatusBlock
+	info  afdPollInfo
+	ident int
+	sock  syscall.Handle
+}
+
+// poller drives an IOCP and a pool of outstanding AFD poll requests, one per
+// watched fd, translating completions back into readiness events.
+type poller struct {
+	iocp syscall.Handle
+	afd  syscall.Handle
+
+	mu      sync.Mutex
+	pending map[int]*pollReq
+
+	die     chan struct{}
+	dieOnce sync.Once
+}
+
+// openPoll creates the IOCP and opens a handle to \Device\Afd bound to it.
+func openPoll() (*poller, error) {
+	iocp, err := createIoCompletionPort(syscall.InvalidHandle, 0, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	afd, err := openAfdDevice(iocp)
+	if err != nil {
+		syscall.CloseHandle(iocp)
+		return nil, err
+	}
+
+	p := &poller{
+		iocp:    iocp,
+		afd:     afd,
+		pending: make(map[int]*pollReq),
+		die:     make(chan struct{}),
+	}
+	return p, nil
+}
+
+// Watch duplicates nothing extra -- 'fd' here is the SOCKET handle already
+// dup()'d out of net.Conn by dupconn, and is armed with a fresh AFD poll
+// request for both readable and writable readiness.
+func (p *poller) Watch(fd int) error {
+	req := &pollReq{ident: fd, sock: syscall.Handle(fd)}
+	req.info.numHandles = 1
+	req.info.handles[0].handle = req.sock
+
+	p.mu.Lock()
+	p.pending[fd] = req
+	p.mu.Unlock()
+
+	return p.arm(req)
+}
+
+// arm (re-)submits the AFD poll IOCTL for a single handle. The ApcContext,
+// which the IOCP hands back as the entry's lpOverlapped, is req.ident (the
+// fd, already unique in p.pending) rather than a raw *pollReq:
+// round-tripping a Go pointer through the kernel as an opaque uintptr
+// across an arbitrary time window violates the unsafe.Pointer rules, so
+// Wait() instead looks the *pollReq back up by id under p.mu.
+func (p *poller) arm(req *pollReq) error {
+	req.iosb = ioStatusBlock{}
+	// AFD overwrites events with the ones that fired; restore the full
+	// read/write mask on every (re-)arm
+	req.info.handles[0].events = afdPollReceive | afdPollSend | afdPollDisconnect | afdPollAbort | afdPollConnectFail
+	req.info.handles[0].status = 0
+	r1, _, _ := procNtDeviceIoControlFile.Call(
+		uintptr(p.afd),
+		0,
+		0,
+		uintptr(req.ident), // ApcContext: returned as lpOverlapped, looked up in p.pending, see Wait()
+		uintptr(unsafe.Pointer(&req.iosb)),
+		uintptr(ioctlAfdPoll),
+		uintptr(unsafe.Pointer(&req.info)),
+		uintptr(unsafe.Sizeof(req.info)),
+		uintptr(unsafe.Pointer(&req.info)),
+		uintptr(unsafe.Sizeof(req.info)),
+	)
+
+	// STATUS_PENDING is expected; the completion arrives via the IOCP.
+	const statusPending = 0x103
+	if r1 != 0 && r1 != statusPending {
+		return syscall.Errno(r1)
+	}
+	return nil
+}
+
+// Wait blocks on GetQueuedCompletionStatusEx and converts AFD completions
+// into the existing event{ident, r, w} shape expected by watcher.loop().
+func (p *poller) Wait(chEventNotify chan pollerEvents, die chan struct{}) {
+	const batch = maxEvents
+	entries := make([]overlappedEntry, batch)
+
+	for {
+		var n uint32
+		ok, _, _ := procGetQueuedCompletionStatusEx.Call(
+			uintptr(p.iocp),
+			uintptr(unsafe.Pointer(&entries[0])),
+			uintptr(batch),
+			uintptr(unsafe.Pointer(&n)),
+			uintptr(0xFFFFFFFF), // INFINITE
+			0,
+		)
+		if ok == 0 {
+			select {
+			case <-p.die:
+				return
+			case <-die:
+				return
+			default:
+				continue
+			}
+		}
+
+		events := make(pollerEvents, 0, n)
+		p.mu.Lock()
+		for i := uint32(0); i < n; i++ {
+			req, ok := p.pending[int(entries[i].overlapped)]
+			if !ok {
+				continue // already removed by Free()/close
+			}
+
+			flags := req.info.handles[0].events
+			ev := event{ident: req.ident}
+			if flags&(afdPollReceive|afdPollDisconnect|afdPollAbort) != 0 {
+				ev.r = true
+			}
+			if flags&(afdPollSend|afdPollConnectFail) != 0 {
+				ev.w = true
+			}
+			events = append(events, ev)
+
+			// re-arm for the next readiness change
+			p.arm(req)
+		}
+		p.mu.Unlock()
+
+		if len(events) == 0 {
+			continue
+		}
+
+		select {
+		case chEventNotify <- events:
+		case <-p.die:
+			return
+		case <-die:
+			return
+		}
+	}
+}
+
+// Free cancels any outstanding IOSB for 'fd' via NtCancelIoFileEx and stops
+// tracking it, mirroring how the epoll/kqueue backends drop a closed fd.
+func (p *poller) Free(fd int) error {
+	p.mu.Lock()
+	req, ok := p.pending[fd]
+	delete(p.pending, fd)
+	p.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	procNtCancelIoFileEx.Call(
+		uintptr(p.afd),
+		uintptr(unsafe.Pointer(&req.iosb)),
+		uintptr(unsafe.Pointer(&ioStatusBlock{})),
+	)
+	return nil
+}
+
+// Close tears down the poller, cancelling every outstanding AFD poll IOSB.
+func (p *poller) Close() error {
+	p.dieOnce.Do(func() {
+		close(p.die)
+	})
+
+	p.mu.Lock()
+	for fd, req := range p.pending {
+		procNtCancelIoFileEx.Call(
+			uintptr(p.afd),
+			uintptr(unsafe.Pointer(&req.iosb)),
+			uintptr(unsafe.Pointer(&ioStatusBlock{})),
+		)
+		delete(p.pending, fd)
+	}
+	p.mu.Unlock()
+
+	syscall.CloseHandle(p.afd)
+	return syscall.CloseHandle(p.iocp)
+}
+
+// overlappedEntry mirrors OVERLAPPED_ENTRY as returned by
+// GetQueuedCompletionStatusEx.
+type overlappedEntry struct {
+	completionKey    uintptr
+	overlapped       uintptr
+	internal         uintptr
+	bytesTransferred uint32
+}
+
+func createIoCompletionPort(fileHandle syscall.Handle, existingPort syscall.Handle, completionKey uintptr, concurrency uint32) (syscall.Handle, error) {
+	r1, _, e1 := procCreateIoCompletionPort.Call(
+		uintptr(fileHandle),
+		uintptr(existingPort),
+		completionKey,
+		uintptr(concurrency),
+	)
+	if r1 == 0 {
+		return 0, e1
+	}
+	return syscall.Handle(r1), nil
+}
+
+// openAfdDevice opens a handle to \Device\Afd and associates it with the
+// watcher's IOCP, following the undocumented-but-stable technique used by
+// mio's windows backend to obtain readiness notifications for arbitrary
+// SOCKET handles.
+func openAfdDevice(iocp syscall.Handle) (syscall.Handle, error) {
+	name, err := syscall.UTF16PtrFromString("\\Device\\Afd\\Gaio")
+	if err != nil {
+		return 0, err
+	}
+
+	var unicodeString struct {
+		length        uint16
+		maximumLength uint16
+		buffer        *uint16
+	}
+	unicodeString.buffer = name
+	l := uint16(0)
+	for p := name; *p != 0; p = (*uint16)(unsafe.Pointer(uintptr(unsafe.Pointer(p)) + 2)) {
+		l += 2
+	}
+	unicodeString.length = l
+	unicodeString.maximumLength = l + 2
+
+	var objAttr struct {
+		length                   uint32
+		rootDirectory            syscall.Handle
+		objectName               uintptr
+		attributes               uint32
+		securityDescriptor       uintptr
+		securityQualityOfService uintptr
+	}
+	objAttr.length = uint32(unsafe.Sizeof(objAttr))
+	objAttr.objectName = uintptr(unsafe.Pointer(&unicodeString))
+
+	var handle syscall.Handle
+	var iosb ioStatusBlock
+	const fileOpen = 1
+	const synchronizeAndGenericRead = 0x100000 | 0x80000000
+
+	r1, _, _ := procNtCreateFile.Call(
+		uintptr(unsafe.Pointer(&handle)),
+		uintptr(synchronizeAndGenericRead),
+		uintptr(unsafe.Pointer(&objAttr)),
+		uintptr(unsafe.Pointer(&iosb)),
+		0, 0,
+		uintptr(syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE),
+		uintptr(fileOpen),
+		0, 0, 0,
+	)
+	if r1 != 0 {
+		return 0, syscall.Errno(r1)
+	}
+
+	if _, err := createIoCompletionPort(handle, iocp, 0, 0); err != nil {
+		syscall.CloseHandle(handle)
+		return 0, err
+	}
+	return handle, nil
+}
+
+// dupconn duplicates the SOCKET handle underlying conn within the current
+// process, the windows equivalent of aio_unix.go's syscall.Dup (which does
+// not exist on this platform).
+func dupconn(conn net.Conn) (newfd int, err error) {
+	sc, ok := conn.(interface {
+		SyscallConn() (syscall.RawConn, error)
+	})
+	if !ok {
+		return -1, ErrUnsupported
+	}
+	rc, err := sc.SyscallConn()
+	if err != nil {
+		return -1, ErrUnsupported
+	}
+
+	proc, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return -1, err
+	}
+
+	var dup syscall.Handle
+	ec := rc.Control(func(fd uintptr) {
+		err = syscall.DuplicateHandle(proc, syscall.Handle(fd), proc, &dup, 0, false, syscall.DUPLICATE_SAME_ACCESS)
+	})
+	if ec != nil {
+		return -1, ec
+	}
+	if err != nil {
+		return -1, err
+	}
+	return int(dup), nil
+}
+
+// sysRead, sysWrite and sysClose adapt gaio's plain-int idents to the
+// syscall.Handle values windows' syscall package expects.
+func sysRead(fd int, buf []byte) (int, error)  { return syscall.Read(syscall.Handle(fd), buf) }
+func sysWrite(fd int, buf []byte) (int, error) { return syscall.Write(syscall.Handle(fd), buf) }
+func sysClose(fd int) error                    { return syscall.Close(syscall.Handle(fd)) }
+
+// sysReadv and sysWritev: windows has no readv(2)/writev(2) equivalent
+// reachable from the standard syscall package, so vectored io always fails
+// explicitly here rather than silently reading/writing only part of the
+// request.
+func sysReadv(fd int, iovs [][]byte) (int, error)  { return 0, ErrUnsupported }
+func sysWritev(fd int, iovs [][]byte) (int, error) { return 0, ErrUnsupported }
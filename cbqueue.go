@@ -0,0 +1,62 @@
+package gaio
+
+// cbQueue is an intrusive doubly linked list of *aiocb, used for a fdDesc's
+// readers/writers queues. It replaces container/list.List: aiocb carries
+// its own qPrev/qNext/qHome linkage, so there is no separate list.Element
+// to allocate or look up on removal.
+type cbQueue struct {
+	sentinel aiocb // never a real request, just the ring's fixed point
+	len      int
+}
+
+func newCBQueue() *cbQueue {
+	q := &cbQueue{}
+	q.sentinel.qNext = &q.sentinel
+	q.sentinel.qPrev = &q.sentinel
+	return q
+}
+
+// Len returns the number of requests currently queued.
+func (q *cbQueue) Len() int {
+	return q.len
+}
+
+// Front returns the first queued request, or nil if the queue is empty.
+func (q *cbQueue) Front() *aiocb {
+	if q.sentinel.qNext == &q.sentinel {
+		return nil
+	}
+	return q.sentinel.qNext
+}
+
+// Next returns the request following cb, or nil if cb is the last one.
+func (q *cbQueue) Next(cb *aiocb) *aiocb {
+	if cb.qNext == &q.sentinel {
+		return nil
+	}
+	return cb.qNext
+}
+
+// PushBack appends cb to the queue.
+func (q *cbQueue) PushBack(cb *aiocb) {
+	cb.qNext = &q.sentinel
+	cb.qPrev = q.sentinel.qPrev
+	q.sentinel.qPrev.qNext = cb
+	q.sentinel.qPrev = cb
+	cb.qHome = q
+	q.len++
+}
+
+// Remove unlinks cb from the queue. A no-op if cb is not currently in this
+// queue (already removed, or never inserted).
+func (q *cbQueue) Remove(cb *aiocb) {
+	if cb.qHome != q {
+		return
+	}
+	cb.qPrev.qNext = cb.qNext
+	cb.qNext.qPrev = cb.qPrev
+	cb.qPrev = nil
+	cb.qNext = nil
+	cb.qHome = nil
+	q.len--
+}
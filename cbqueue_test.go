@@ -0,0 +1,60 @@
+package gaio
+
+import "testing"
+
+// TestCBQueuePushFrontNextOrder verifies the intrusive doubly linked list
+// preserves FIFO order through Front/Next, the traversal the watcher's
+// loop() relies on.
+func TestCBQueuePushFrontNextOrder(t *testing.T) {
+	q := newCBQueue()
+	cbs := []*aiocb{{}, {}, {}}
+	for _, cb := range cbs {
+		q.PushBack(cb)
+	}
+
+	if q.Len() != len(cbs) {
+		t.Fatalf("Len() = %d, want %d", q.Len(), len(cbs))
+	}
+
+	cur := q.Front()
+	for i, want := range cbs {
+		if cur != want {
+			t.Fatalf("position %d: got %p, want %p", i, cur, want)
+		}
+		cur = q.Next(cur)
+	}
+	if cur != nil {
+		t.Fatalf("expected nil after last element, got %p", cur)
+	}
+}
+
+// TestCBQueueRemoveMiddle verifies removing a non-head, non-tail element
+// relinks its neighbors correctly and leaves qHome cleared.
+func TestCBQueueRemoveMiddle(t *testing.T) {
+	q := newCBQueue()
+	a, b, c := &aiocb{}, &aiocb{}, &aiocb{}
+	q.PushBack(a)
+	q.PushBack(b)
+	q.PushBack(c)
+
+	q.Remove(b)
+
+	if q.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", q.Len())
+	}
+	if b.qHome != nil {
+		t.Fatal("removed cb still has qHome set")
+	}
+	if got := q.Front(); got != a {
+		t.Fatalf("Front() = %p, want %p", got, a)
+	}
+	if got := q.Next(a); got != c {
+		t.Fatalf("Next(a) = %p, want %p", got, c)
+	}
+
+	// removing again must be a no-op, not a double-unlink
+	q.Remove(b)
+	if q.Len() != 2 {
+		t.Fatalf("double Remove() changed Len() to %d", q.Len())
+	}
+}
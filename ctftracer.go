@@ -0,0 +1,49 @@
+package gaio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"sync"
+)
+
+// CTFTracer is a built-in Tracer that appends a compact binary trace to an
+// io.Writer: each record is [1 byte TraceEventType][uvarint nanosecond
+// timestamp][zigzag varint ident]. Ctx is intentionally not recorded, since
+// it is opaque user data with no stable binary encoding; external tooling
+// correlating records can join on timestamp/ident instead. The resulting
+// stream is small and append-only, intended for a separate tool to convert
+// into a flamegraph or Chrome trace-viewer JSON.
+type CTFTracer struct {
+	mu  sync.Mutex
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+}
+
+// NewCTFTracer wraps 'w' for buffered, concurrency-safe trace record
+// writes. Call Flush when done to ensure the last records are written out;
+// closing the underlying writer does not flush records still buffered here.
+func NewCTFTracer(w io.Writer) *CTFTracer {
+	return &CTFTracer{w: bufio.NewWriter(w)}
+}
+
+// Trace implements Tracer.
+func (t *CTFTracer) Trace(ev TraceEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.w.WriteByte(byte(ev.Type))
+
+	n := binary.PutUvarint(t.buf[:], uint64(ev.Timestamp))
+	t.w.Write(t.buf[:n])
+
+	n = binary.PutVarint(t.buf[:], int64(ev.Ident))
+	t.w.Write(t.buf[:n])
+}
+
+// Flush flushes any buffered trace records to the underlying writer.
+func (t *CTFTracer) Flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.w.Flush()
+}
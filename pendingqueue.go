@@ -0,0 +1,75 @@
+package gaio
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// pendingQueue is Dmitry Vyukov's intrusive MPSC (multi-producer,
+// single-consumer) node-based queue: https://www.1024cores.net/ -- any
+// number of goroutines may Push concurrently (one per Read/Write call),
+// but only the loop goroutine may call Pop. It replaces the
+// mutex-protected pending slice, removing both the lock contention between
+// concurrent submitters and the per-wakeup slice copy.
+//
+// aiocb carries its own pendNext link, so pushing a request costs no
+// extra allocation beyond the aiocb itself (which comes from the
+// Watcher's sync.Pool).
+type pendingQueue struct {
+	head unsafe.Pointer // *aiocb, producers swap this
+	tail *aiocb         // consumer-owned
+	stub aiocb          // fixed dummy node, never a real request
+}
+
+func newPendingQueue() *pendingQueue {
+	q := &pendingQueue{}
+	q.tail = &q.stub
+	q.head = unsafe.Pointer(&q.stub)
+	return q
+}
+
+// Push enqueues n. Safe for concurrent use by multiple producers.
+func (q *pendingQueue) Push(n *aiocb) {
+	atomic.StorePointer(&n.pendNext, nil)
+	prev := (*aiocb)(atomic.SwapPointer(&q.head, unsafe.Pointer(n)))
+	atomic.StorePointer(&prev.pendNext, unsafe.Pointer(n))
+}
+
+// Pop dequeues and returns the oldest pending request, or nil if the queue
+// is currently empty. Must only be called from a single consumer
+// goroutine. May spuriously return nil while a concurrent Push is
+// mid-flight even though the queue is logically non-empty; the caller is
+// expected to be woken again (see Watcher.notifyPending) and retry.
+func (q *pendingQueue) Pop() *aiocb {
+	tail := q.tail
+	next := (*aiocb)(atomic.LoadPointer(&tail.pendNext))
+
+	if tail == &q.stub {
+		if next == nil {
+			return nil // empty
+		}
+		q.tail = next
+		tail = next
+		next = (*aiocb)(atomic.LoadPointer(&tail.pendNext))
+	}
+
+	if next != nil {
+		q.tail = next
+		return tail
+	}
+
+	if head := (*aiocb)(atomic.LoadPointer(&q.head)); tail != head {
+		return nil // a push is in flight, its link isn't visible yet
+	}
+
+	// tail caught up to head: park the stub so the next Push still has
+	// somewhere to link onto, then check once more for the race where
+	// the in-flight push finished between the two loads above.
+	q.Push(&q.stub)
+	next = (*aiocb)(atomic.LoadPointer(&tail.pendNext))
+	if next != nil {
+		q.tail = next
+		return tail
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package gaio
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// pipeBufferSize caps how much unread data a pipeHalf holds before Write
+// starts returning ErrWouldBlock, emulating the backpressure of a real
+// socket send/receive buffer.
+const pipeBufferSize = 64 * 1024
+
+// pipeAddr is a placeholder net.Addr for Pipe() endpoints, which have no
+// real network address.
+type pipeAddr string
+
+func (a pipeAddr) Network() string { return "pipe" }
+func (a pipeAddr) String() string  { return string(a) }
+
+// pipeHalf is one direction of a Pipe(): a bounded byte buffer plus the
+// readiness channels PollableConn exposes. It is shared between the reading
+// side and the writing side of that direction.
+type pipeHalf struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	closed bool
+
+	readyRead  chan struct{} // signaled when data becomes available to read
+	readyWrite chan struct{} // signaled when room becomes available to write
+}
+
+func newPipeHalf() *pipeHalf {
+	h := &pipeHalf{
+		readyRead:  make(chan struct{}, 1),
+		readyWrite: make(chan struct{}, 1),
+	}
+	// a fresh half has its whole buffer free, mirroring a real socket
+	// being writable as soon as it's connected; without this there is no
+	// event to drive the very first Write, which would otherwise stall
+	// until some later read/write made room and re-armed PollWrite.
+	h.signal(h.readyWrite)
+	return h
+}
+
+func (h *pipeHalf) signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// read mirrors a non-blocking socket read: ErrWouldBlock when empty, (0,
+// nil) on a graceful close with nothing left, matching how tryRead already
+// treats a zero-byte nil-error syscall.Read as the peer closing.
+func (h *pipeHalf) read(p []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.buf.Len() == 0 {
+		if h.closed {
+			return 0, nil
+		}
+		return 0, ErrWouldBlock
+	}
+
+	n, _ := h.buf.Read(p)
+	h.signal(h.readyWrite) // room freed for the writer
+
+	if h.buf.Len() > 0 {
+		h.signal(h.readyRead) // data still pending, re-arm for the reader
+	}
+	return n, nil
+}
+
+// write mirrors a non-blocking socket write: ErrWouldBlock once the bounded
+// buffer is full, a partial write if only some of p fits.
+func (h *pipeHalf) write(p []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	free := pipeBufferSize - h.buf.Len()
+	if free <= 0 {
+		return 0, ErrWouldBlock
+	}
+	if free < len(p) {
+		p = p[:free]
+	}
+
+	n, _ := h.buf.Write(p)
+	h.signal(h.readyRead)
+
+	if pipeBufferSize-h.buf.Len() > 0 {
+		h.signal(h.readyWrite) // still room, re-arm for the writer
+	}
+	return n, nil
+}
+
+func (h *pipeHalf) close() {
+	h.mu.Lock()
+	h.closed = true
+	h.mu.Unlock()
+	h.signal(h.readyRead)
+	h.signal(h.readyWrite)
+}
+
+// pipeConn is one end of an in-memory, full-duplex connection returned by
+// Pipe(). It implements PollableConn so it can be driven by gaio's
+// goroutine-backed pseudo-poller without a real fd.
+type pipeConn struct {
+	rx, tx       *pipeHalf
+	laddr, raddr net.Addr
+}
+
+// Pipe returns a pair of connected in-memory PollableConns, the gaio
+// equivalent of net.Pipe() for use with Watcher in tests and other
+// pure-Go environments where a real socket isn't available or wanted.
+func Pipe() (PollableConn, PollableConn) {
+	a, b := newPipeHalf(), newPipeHalf()
+	c1 := &pipeConn{rx: a, tx: b, laddr: pipeAddr("gaio.Pipe/1"), raddr: pipeAddr("gaio.Pipe/2")}
+	c2 := &pipeConn{rx: b, tx: a, laddr: pipeAddr("gaio.Pipe/2"), raddr: pipeAddr("gaio.Pipe/1")}
+	return c1, c2
+}
+
+func (c *pipeConn) Read(p []byte) (int, error)  { return c.rx.read(p) }
+func (c *pipeConn) Write(p []byte) (int, error) { return c.tx.write(p) }
+
+// Close closes both halves of the pipe: c.rx so this side's own pending
+// reads unblock, and c.tx (the peer's rx) so the peer observes EOF/closed
+// state too, matching how closing a real socket is visible from both ends.
+func (c *pipeConn) Close() error {
+	c.rx.close()
+	c.tx.close()
+	return nil
+}
+
+func (c *pipeConn) PollRead() <-chan struct{}  { return c.rx.readyRead }
+func (c *pipeConn) PollWrite() <-chan struct{} { return c.tx.readyWrite }
+
+func (c *pipeConn) LocalAddr() net.Addr  { return c.laddr }
+func (c *pipeConn) RemoteAddr() net.Addr { return c.raddr }
+
+func (c *pipeConn) SetDeadline(t time.Time) error      { return nil }
+func (c *pipeConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *pipeConn) SetWriteDeadline(t time.Time) error { return nil }
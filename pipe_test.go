@@ -0,0 +1,39 @@
+package gaio
+
+import "testing"
+
+// TestPipeCloseClosesBothHalves verifies that closing one end of a Pipe()
+// is observed by the peer as a graceful close (0, nil), not left hanging
+// on ErrWouldBlock forever.
+func TestPipeCloseClosesBothHalves(t *testing.T) {
+	c1, c2 := Pipe()
+
+	if err := c1.Close(); err != nil {
+		t.Fatalf("c1.Close() = %v, want nil", err)
+	}
+
+	buf := make([]byte, 16)
+	n, err := c2.Read(buf)
+	if err != nil || n != 0 {
+		t.Fatalf("c2.Read() after peer Close() = (%d, %v), want (0, nil)", n, err)
+	}
+}
+
+// TestPipeInitialWritable verifies that a freshly created Pipe() half is
+// immediately writable, mirroring a real socket being writable right after
+// connect, so the very first Write against it doesn't stall waiting for a
+// readiness event that never arrives.
+func TestPipeInitialWritable(t *testing.T) {
+	c1, c2 := Pipe()
+
+	select {
+	case <-c1.PollWrite():
+	default:
+		t.Fatal("c1.PollWrite() not ready immediately after Pipe()")
+	}
+	select {
+	case <-c2.PollWrite():
+	default:
+		t.Fatal("c2.PollWrite() not ready immediately after Pipe()")
+	}
+}
@@ -0,0 +1,54 @@
+package gaio
+
+import (
+	"testing"
+	"time"
+)
+
+// TestReadvWritev exercises vectored I/O end to end over a real socket
+// pair, checking that Writev gathers from multiple buffers and Readv
+// scatters into multiple buffers.
+func TestReadvWritev(t *testing.T) {
+	w, err := NewWatcherSize(65536)
+	if err != nil {
+		t.Fatalf("NewWatcherSize: %v", err)
+	}
+	defer w.Close()
+
+	a, b := tcpLoopback(t)
+	defer a.Close()
+	defer b.Close()
+
+	wbuf1 := []byte("hello, ")
+	wbuf2 := []byte("world!")
+	readBufs := [][]byte{make([]byte, 7), make([]byte, 6)}
+
+	if _, err := w.Readv(nil, b, readBufs, time.Now().Add(3*time.Second)); err != nil {
+		t.Fatalf("Readv: %v", err)
+	}
+	if _, err := w.Writev(nil, a, [][]byte{wbuf1, wbuf2}, time.Now().Add(3*time.Second)); err != nil {
+		t.Fatalf("Writev: %v", err)
+	}
+
+	seenWrite, seenRead := false, false
+	for !seenWrite || !seenRead {
+		results, err := w.WaitIO()
+		if err != nil {
+			t.Fatalf("WaitIO: %v", err)
+		}
+		for _, r := range results {
+			if r.Error != nil {
+				t.Fatalf("op %v failed: %v", r.Operation, r.Error)
+			}
+			switch r.Operation {
+			case OpWrite:
+				seenWrite = true
+			case OpRead:
+				seenRead = true
+				if got := string(readBufs[0]) + string(readBufs[1]); got != "hello, world!" {
+					t.Fatalf("Readv scattered %q, want %q", got, "hello, world!")
+				}
+			}
+		}
+	}
+}
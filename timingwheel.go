@@ -0,0 +1,179 @@
+package gaio
+
+import "time"
+
+// timing wheel tuning: 4 levels of 256 slots each on a 1ms tick covers
+// everything from sub-millisecond to multi-hour deadlines while keeping
+// insertion, removal and per-tick bookkeeping O(1), unlike the heap-based
+// scheme it replaces.
+const (
+	wheelTickDuration  = time.Millisecond
+	wheelLevels        = 4
+	wheelSlotsPerLevel = 256
+)
+
+// wheelLevel is one ring of slots; slots[i] is the sentinel head of a
+// circular intrusive doubly linked list of *aiocb due in that slot.
+type wheelLevel struct {
+	slots []*aiocb
+	pos   int
+}
+
+func newWheelLevel(slotsPerLevel int) *wheelLevel {
+	lv := &wheelLevel{slots: make([]*aiocb, slotsPerLevel)}
+	for i := range lv.slots {
+		sentinel := &aiocb{}
+		sentinel.twNext = sentinel
+		sentinel.twPrev = sentinel
+		lv.slots[i] = sentinel
+	}
+	return lv
+}
+
+// timingWheel is a hierarchical timing wheel (Varghese & Lauck) scheduling
+// aiocb deadlines. Level 0 advances one slot per tick; when it wraps, the
+// next level's current slot is cascaded back down into the lower levels at
+// their now-precise slot, exactly like cascading in a mechanical clock.
+//
+// If a platform lacks a monotonic clock source, time.Now() degrades to
+// wall-clock time and the wheel keeps working off deltas between
+// successive ticks; it is never compared against an absolute baseline, so
+// it cannot be confused by an NTP step the way the old heap's absolute
+// time.Time comparisons could.
+type timingWheel struct {
+	tick          time.Duration
+	slotsPerLevel int
+	levels        []*wheelLevel
+	ticks         uint64
+}
+
+func newTimingWheel(tick time.Duration, levels, slotsPerLevel int) *timingWheel {
+	tw := &timingWheel{tick: tick, slotsPerLevel: slotsPerLevel}
+	tw.levels = make([]*wheelLevel, levels)
+	for i := range tw.levels {
+		tw.levels[i] = newWheelLevel(slotsPerLevel)
+	}
+	return tw
+}
+
+// Add schedules cb to expire at cb.deadline, relative to 'now'.
+func (tw *timingWheel) Add(cb *aiocb, now time.Time) {
+	delay := cb.deadline.Sub(now)
+	if delay < 0 {
+		delay = 0
+	}
+	ticks := uint64(delay / tw.tick)
+	if ticks == 0 {
+		// the current slot has already been drained by Advance; the
+		// earliest slot it still visits is one tick ahead
+		ticks = 1
+	}
+	tw.insert(cb, tw.ticks+ticks)
+}
+
+// Remove unlinks cb from whatever slot currently holds it. Safe to call on
+// an already-fired or never-scheduled cb.
+func (tw *timingWheel) Remove(cb *aiocb) {
+	if cb.twHome == nil {
+		return
+	}
+	cb.twPrev.twNext = cb.twNext
+	cb.twNext.twPrev = cb.twPrev
+	cb.twPrev = nil
+	cb.twNext = nil
+	cb.twHome = nil
+}
+
+// Advance moves the wheel forward by one tick, cascading higher levels down
+// as their slot pointers wrap, and returns every aiocb expiring on this
+// tick (already unlinked from the wheel).
+func (tw *timingWheel) Advance() []*aiocb {
+	var expired []*aiocb
+
+	// bump the tick counter before cascading: a cascaded entry's
+	// twExpireAt is an absolute tick, and insert() places it by computing
+	// ticksAway relative to tw.ticks, so tw.ticks must already reflect
+	// *this* tick or every cascaded entry lands one slot (one tick) later
+	// than it should.
+	tw.ticks++
+
+	cascade := true
+	for lvl := 0; cascade && lvl < len(tw.levels); lvl++ {
+		level := tw.levels[lvl]
+		level.pos = (level.pos + 1) % tw.slotsPerLevel
+		entries := detachSlot(level.slots[level.pos])
+
+		if lvl == 0 {
+			expired = entries
+		} else {
+			// re-distribute into their now-precise lower-level slot,
+			// unless they're due exactly on this tick: level 0's slot for
+			// this tick was already drained above, so re-inserting would
+			// strand them there until the wheel wraps all the way around
+			for _, cb := range entries {
+				if cb.twExpireAt <= tw.ticks {
+					expired = append(expired, cb)
+				} else {
+					tw.insert(cb, cb.twExpireAt)
+				}
+			}
+		}
+		cascade = level.pos == 0
+	}
+
+	return expired
+}
+
+// insert places cb into the lowest (finest) level whose span still covers
+// the remaining delay, clamping into the last slot of the top level for
+// anything beyond the wheel's total span.
+func (tw *timingWheel) insert(cb *aiocb, expireAt uint64) {
+	cb.twExpireAt = expireAt
+
+	ticksAway := int64(0)
+	if expireAt > tw.ticks {
+		ticksAway = int64(expireAt - tw.ticks)
+	}
+
+	span := int64(1)
+	for lvl := 0; lvl < len(tw.levels); lvl++ {
+		levelSpan := span * int64(tw.slotsPerLevel)
+		if ticksAway < levelSpan || lvl == len(tw.levels)-1 {
+			level := tw.levels[lvl]
+			offset := ticksAway / span
+			if offset >= int64(tw.slotsPerLevel) {
+				offset = int64(tw.slotsPerLevel) - 1
+			}
+			idx := (level.pos + int(offset)) % tw.slotsPerLevel
+			attachSlot(level.slots[idx], cb)
+			return
+		}
+		span = levelSpan
+	}
+}
+
+// attachSlot links cb at the tail of sentinel's ring.
+func attachSlot(sentinel *aiocb, cb *aiocb) {
+	cb.twNext = sentinel
+	cb.twPrev = sentinel.twPrev
+	sentinel.twPrev.twNext = cb
+	sentinel.twPrev = cb
+	cb.twHome = sentinel
+}
+
+// detachSlot empties sentinel's ring, returning its former members with
+// their wheel linkage cleared.
+func detachSlot(sentinel *aiocb) []*aiocb {
+	var out []*aiocb
+	for cb := sentinel.twNext; cb != sentinel; {
+		next := cb.twNext
+		cb.twPrev = nil
+		cb.twNext = nil
+		cb.twHome = nil
+		out = append(out, cb)
+		cb = next
+	}
+	sentinel.twNext = sentinel
+	sentinel.twPrev = sentinel
+	return out
+}
@@ -0,0 +1,35 @@
+package gaio
+
+import "testing"
+
+// TestTimingWheelCascadeExactness is a regression test for the off-by-one
+// cascade bug fixed in chunk0-3: a deadline that requires cascading from a
+// level above 0 (e.g. delay=256 needs one cascade from level 1) used to
+// fire one tick late. Advancing the wheel tick-by-tick and recording which
+// tick actually returns each cb lets us assert the exact fire tick for a
+// range of delays spanning every level.
+func TestTimingWheelCascadeExactness(t *testing.T) {
+	delays := []uint64{1, 255, 256, 257, 5000, 70000}
+
+	for _, delay := range delays {
+		tw := newTimingWheel(wheelTickDuration, wheelLevels, wheelSlotsPerLevel)
+		cb := &aiocb{}
+		tw.insert(cb, tw.ticks+delay)
+
+		var firedAt uint64
+		for tick := uint64(1); tick <= delay+wheelSlotsPerLevel; tick++ {
+			expired := tw.Advance()
+			if len(expired) > 0 {
+				firedAt = tick
+				if expired[0] != cb {
+					t.Fatalf("delay=%d: unexpected cb fired: %v", delay, expired)
+				}
+				break
+			}
+		}
+
+		if firedAt != delay {
+			t.Fatalf("delay=%d: fired at tick %d, want %d", delay, firedAt, delay)
+		}
+	}
+}
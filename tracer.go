@@ -0,0 +1,91 @@
+package gaio
+
+import "time"
+
+// TraceEventType enumerates the kinds of event-loop lifecycle events a
+// Tracer can observe.
+type TraceEventType int
+
+const (
+	// OpSubmitted fires when Read/Write/Readv/Writev/their *Timeout
+	// variants hand a new aiocb to the loop. Ident is 0, as no fd has
+	// been assigned yet at submission time.
+	OpSubmitted TraceEventType = iota
+	// OpPolled fires every time the loop attempts a read or write on a
+	// ready fd, whether or not it completes.
+	OpPolled
+	// OpCompleted fires once an attempted read or write finishes, with
+	// success or error.
+	OpCompleted
+	// ConnRegistered fires when a net.Conn is first seen and bound to an
+	// ident, either via a duplicated fd or a PollableConn registration.
+	ConnRegistered
+	// ConnReleased fires when a conn's resources are torn down, via
+	// Free(), an IO error, GC, or Watcher.Close().
+	ConnReleased
+	// DeadlineFired fires for each aiocb the timing wheel expires.
+	DeadlineFired
+	// PollerWake fires once per batch of events received from the
+	// poller; Ident carries the batch size.
+	PollerWake
+	// SwapBufferRotated fires when a nil-buffer Read() completes and the
+	// watcher rotates to the next internal swap buffer.
+	SwapBufferRotated
+)
+
+// TraceEvent is a single point-in-time observation of the event loop.
+type TraceEvent struct {
+	Type TraceEventType
+	// Timestamp is nanoseconds elapsed on a monotonic clock since the
+	// gaio package was loaded (see traceEpoch), not a wall-clock epoch.
+	// Ordering and deltas between TraceEvents are meaningful even across
+	// an NTP step or other wall-clock adjustment; the absolute value is
+	// not.
+	Timestamp int64
+	// Ident is usually the fd/ident this event concerns; meaning varies
+	// by Type, see the TraceEventType docs above.
+	Ident int
+	// Ctx is the user context associated with the related request, if
+	// any, unchanged from what was passed to Read/Write/etc.
+	Ctx interface{}
+}
+
+// Tracer receives TraceEvents emitted by a Watcher's event loop. Trace must
+// not block or call back into the Watcher; the loop calls it inline on its
+// own goroutine, so slow tracers should queue internally. OpSubmitted is
+// delivered on the goroutine that submitted the request instead, so Trace
+// may be called concurrently.
+type Tracer interface {
+	Trace(ev TraceEvent)
+}
+
+// SetTracer attaches t to the watcher's event loop; pass nil to detach.
+// Safe to call concurrently with Read/Write and from any goroutine.
+func (w *Watcher) SetTracer(t Tracer) {
+	w.tracerBox.Store(&tracerBox{t: t})
+}
+
+// tracerBox lets Tracer (an interface) be stored in the Watcher's
+// atomic.Value, which requires every Store to use the same concrete type.
+type tracerBox struct {
+	t Tracer
+}
+
+// traceEpoch anchors TraceEvent.Timestamp. time.Since(traceEpoch) carries
+// time.Now()'s monotonic reading through to an int64 nanosecond count,
+// where calling .UnixNano() directly on time.Now() would have stripped it.
+var traceEpoch = time.Now()
+
+// trace delivers a TraceEvent to the attached Tracer, if any. Cheap no-op
+// when no tracer is set.
+func (w *Watcher) trace(typ TraceEventType, ident int, ctx interface{}) {
+	v := w.tracerBox.Load()
+	if v == nil {
+		return
+	}
+	box := v.(*tracerBox)
+	if box.t == nil {
+		return
+	}
+	box.t.Trace(TraceEvent{Type: typ, Timestamp: int64(time.Since(traceEpoch)), Ident: ident, Ctx: ctx})
+}
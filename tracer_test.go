@@ -0,0 +1,100 @@
+package gaio
+
+import (
+	"sync"
+	"testing"
+)
+
+// collectingTracer records every TraceEvent delivered to it, guarding
+// against concurrent delivery from the loop and submitting goroutines.
+type collectingTracer struct {
+	mu     sync.Mutex
+	events []TraceEvent
+}
+
+func (c *collectingTracer) Trace(ev TraceEvent) {
+	c.mu.Lock()
+	c.events = append(c.events, ev)
+	c.mu.Unlock()
+}
+
+func (c *collectingTracer) snapshot() []TraceEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]TraceEvent, len(c.events))
+	copy(out, c.events)
+	return out
+}
+
+// TestTracerMonotonicOrdering is a regression test for the wall-clock vs
+// monotonic timestamp bug: trace() used to stamp events with
+// time.Now().UnixNano(), which strips the monotonic reading, so timestamps
+// were only as reliable as the wall clock. Driving a real Read/Write round
+// trip and checking that every recorded Timestamp is non-decreasing in
+// delivery order demonstrates the monotonic clock is actually in use.
+func TestTracerMonotonicOrdering(t *testing.T) {
+	w, err := NewWatcherSize(65536)
+	if err != nil {
+		t.Fatalf("NewWatcherSize: %v", err)
+	}
+	defer w.Close()
+
+	tracer := &collectingTracer{}
+	w.SetTracer(tracer)
+
+	a, b := tcpLoopback(t)
+	defer a.Close()
+	defer b.Close()
+
+	if _, err := w.Write(nil, a, []byte("ping")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, err := w.Read(nil, b, make([]byte, 4)); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	seenWrite, seenRead := false, false
+	for !seenWrite || !seenRead {
+		results, err := w.WaitIO()
+		if err != nil {
+			t.Fatalf("WaitIO: %v", err)
+		}
+		for _, r := range results {
+			if r.Error != nil {
+				t.Fatalf("op %v failed: %v", r.Operation, r.Error)
+			}
+			switch r.Operation {
+			case OpWrite:
+				seenWrite = true
+			case OpRead:
+				seenRead = true
+			}
+		}
+	}
+
+	events := tracer.snapshot()
+	if len(events) == 0 {
+		t.Fatal("no trace events recorded")
+	}
+
+	var last int64
+	for i, ev := range events {
+		if ev.Timestamp < last {
+			t.Fatalf("event %d (type %v) timestamp %d precedes prior timestamp %d",
+				i, ev.Type, ev.Timestamp, last)
+		}
+		last = ev.Timestamp
+	}
+
+	// sanity: the round trip should have produced at least submit/poll/
+	// complete events, not just a single no-op observation.
+	seenTypes := make(map[TraceEventType]bool)
+	for _, ev := range events {
+		seenTypes[ev.Type] = true
+	}
+	for _, typ := range []TraceEventType{OpSubmitted, OpPolled, OpCompleted} {
+		if !seenTypes[typ] {
+			t.Fatalf("no trace event of type %v recorded", typ)
+		}
+	}
+}
@@ -5,15 +5,15 @@
 package gaio
 
 import (
-	"container/heap"
-	"container/list"
 	"errors"
 	"net"
 	"reflect"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
+	"unsafe"
 )
 
 var (
@@ -29,8 +29,40 @@ var (
 	ErrDeadline = errors.New("operation exceeded deadline")
 	// ErrEmptyBuffer means the buffer is nil
 	ErrEmptyBuffer = errors.New("empty buffer")
+	// ErrCanceled means the operation has been canceled via Watcher.Cancel
+	ErrCanceled = errors.New("operation canceled")
+	// ErrRequestNotFound means Cancel was called with an id that is not
+	// (or no longer) pending
+	ErrRequestNotFound = errors.New("request not found")
+	// ErrWouldBlock is returned by a PollableConn's Read/Write to indicate
+	// the operation is not ready yet, mirroring syscall.EAGAIN for conns
+	// that have no underlying fd to poll with epoll/kqueue.
+	ErrWouldBlock = errors.New("operation would block")
+	// ErrPollableNoVector means Readv/Writev was issued against a
+	// PollableConn, which gaio only drives through single-buffer Read/Write
+	// and has no vectored I/O primitive to fall back to.
+	ErrPollableNoVector = errors.New("PollableConn does not support vectored io")
 )
 
+// defaultPollableBufferSize sizes the internal buffer used for a nil-buffer
+// Read() against a PollableConn, mirroring Watcher's real swapBuffer.
+const defaultPollableBufferSize = 65536
+
+// PollableConn is implemented by connections that cannot provide a
+// SyscallConn fast path -- e.g. net.Pipe()-style in-memory transports used
+// in tests -- but can still be driven by gaio's proactor loop through a
+// goroutine-backed pseudo-poller. Read/Write must behave like a
+// non-blocking socket, returning ErrWouldBlock instead of blocking when no
+// data/room is currently available. PollRead/PollWrite return a channel
+// that becomes readable whenever the connection transitions into a
+// readable/writable state; it does not need to stay readable, the watcher
+// re-arms by calling PollRead/PollWrite again after each wakeup.
+type PollableConn interface {
+	net.Conn
+	PollRead() <-chan struct{}
+	PollWrite() <-chan struct{}
+}
+
 var (
 	zeroTime = time.Time{}
 )
@@ -47,10 +79,13 @@ const (
 	opDelete
 )
 
+// RequestID uniquely identifies a submitted Read/Write operation so it can
+// later be passed to Watcher.Cancel.
+type RequestID uint64
+
 // aiocb contains all info for a request
 type aiocb struct {
-	l        *list.List // list where this request belongs to
-	elem     *list.Element
+	id       RequestID   // unique id of this request, see Watcher.Cancel
 	ctx      interface{} // user context associated with this request
 	ptr      uintptr     // pointer to conn
 	op       OpType      // read or write
@@ -58,9 +93,42 @@ type aiocb struct {
 	err      error       // error for last operation
 	size     int         // size received or sent
 	buffer   []byte
-	useSwap  bool // mark if the buffer is internal swap
-	idx      int  // index for heap op
+	buffers  [][]byte // vectored buffer, mutually exclusive with buffer; head is consumed as iov is advanced
+	useSwap  bool     // mark if the buffer is internal swap
 	deadline time.Time
+
+	// pollConn is set when this request targets a conn registered through
+	// the PollableConn path, routing tryRead/tryWrite through it instead
+	// of syscall.Read/Write on a duplicated fd
+	pollConn PollableConn
+
+	// intrusive linkage into the timing wheel's slot ring; twHome is the
+	// slot's sentinel, nil when the aiocb is not currently scheduled
+	twPrev, twNext, twHome *aiocb
+	twExpireAt             uint64
+
+	// intrusive linkage into a fdDesc's readers/writers cbQueue; qHome is
+	// the queue's sentinel, nil when not currently queued there
+	qPrev, qNext *aiocb
+	qHome        *cbQueue
+
+	// intrusive linkage onto Watcher.pendingQ, the lock-free MPSC queue
+	// submitted requests travel through before the loop goroutine sees them
+	pendNext unsafe.Pointer
+}
+
+// reset clears a pooled aiocb back to its zero value before reuse, except
+// for fields the pool itself doesn't touch (none currently).
+func (cb *aiocb) reset() {
+	*cb = aiocb{}
+}
+
+// cancelRequest is submitted on chCancel to ask the event loop to drop a
+// still-pending aiocb identified by id, serializing the removal with the
+// loop's own timing wheel/queue mutations.
+type cancelRequest struct {
+	id     RequestID
+	result chan error
 }
 
 // readable & writable bitmask
@@ -71,10 +139,15 @@ const (
 
 // fdDesc contains all info related to fd
 type fdDesc struct {
-	status  byte      // fd read/write status
-	readers list.List // all read/write requests
-	writers list.List
-	ptr     uintptr // pointer to net.Conn
+	status  byte     // fd read/write status
+	readers *cbQueue // all pending read requests
+	writers *cbQueue // all pending write requests
+	ptr     uintptr  // pointer to net.Conn
+
+	// set for conns registered through the PollableConn path instead of a
+	// real duplicated fd; pollStop tears down the goroutine driving it
+	pollable PollableConn
+	pollStop chan struct{}
 }
 
 // OpResult is the result of an aysnc-io
@@ -87,6 +160,9 @@ type OpResult struct {
 	Conn net.Conn
 	// Buffer points to user's supplied buffer or watcher's internal swap buffer
 	Buffer []byte
+	// Buffers points to the user's supplied vectored buffers for Readv/Writev,
+	// nil for Read/Write. Already advanced past any fully-consumed elements.
+	Buffers [][]byte
 	// Number of bytes sent or received, Buffer[:Size] is the content sent or received.
 	Size int
 	// IO error,timeout error
@@ -109,10 +185,20 @@ type Watcher struct {
 	swapResults        [][]OpResult
 	swapIdx            int
 
-	// lock for pending io operations
-	// aiocb is associated to fd
-	pending      []*aiocb
-	pendingMutex sync.Mutex
+	// lock-free MPSC queue of submitted-but-not-yet-processed requests;
+	// any goroutine may Push, only loop() may Pop
+	pendingQ *pendingQueue
+
+	// recycles aiocbs across requests to avoid a heap allocation per
+	// Read/Write call
+	cbPool sync.Pool
+
+	// cancellation of a pending aiocb by RequestID
+	chCancel chan cancelRequest
+	nextID   uint64
+
+	// optional observer of event-loop lifecycle events, see SetTracer
+	tracerBox atomic.Value
 
 	// internal buffer for reading
 	swapBuffer     [][]byte
@@ -136,8 +222,12 @@ func NewWatcherSize(bufsize int) (*Watcher, error) {
 	w.chEventNotify = make(chan pollerEvents)
 	w.chPendingNotify = make(chan struct{}, 1)
 	w.chNotifyCompletion = make(chan []OpResult)
+	w.chCancel = make(chan cancelRequest)
 	w.die = make(chan struct{})
 
+	w.pendingQ = newPendingQueue()
+	w.cbPool.New = func() interface{} { return new(aiocb) }
+
 	// swapBuffer for shared reading
 	w.swapBuffer = make([][]byte, 2)
 	for i := 0; i < len(w.swapBuffer); i++ {
@@ -179,6 +269,51 @@ func (w *Watcher) notifyPending() {
 	}
 }
 
+// getCB fetches a recycled aiocb from the pool, already zeroed.
+func (w *Watcher) getCB() *aiocb {
+	return w.cbPool.Get().(*aiocb)
+}
+
+// putCB returns a completed aiocb to the pool for reuse. Must only be
+// called once the loop is completely done with pcb -- after its OpResult
+// has been built (OpResult holds copies of the fields it needs, so it may
+// still be waiting in a batch) and it has been unlinked from any
+// cbQueue/timing wheel.
+func (w *Watcher) putCB(pcb *aiocb) {
+	pcb.reset()
+	w.cbPool.Put(pcb)
+}
+
+// pollPump bridges a PollableConn's readiness channels into the loop's
+// regular chEventNotify stream, acting as a pseudo-poller for conns that
+// have no real fd for epoll/kqueue to watch.
+func (w *Watcher) pollPump(pc PollableConn, ident int, stop chan struct{}) {
+	for {
+		select {
+		case <-pc.PollRead():
+			select {
+			case w.chEventNotify <- pollerEvents{{ident: ident, r: true}}:
+			case <-stop:
+				return
+			case <-w.die:
+				return
+			}
+		case <-pc.PollWrite():
+			select {
+			case w.chEventNotify <- pollerEvents{{ident: ident, w: true}}:
+			case <-stop:
+				return
+			case <-w.die:
+				return
+			}
+		case <-stop:
+			return
+		case <-w.die:
+			return
+		}
+	}
+}
+
 // WaitIO blocks until any read/write completion, or error
 func (w *Watcher) WaitIO() (r []OpResult, err error) {
 	select {
@@ -192,22 +327,24 @@ func (w *Watcher) WaitIO() (r []OpResult, err error) {
 // Read submits an async read request on 'fd' with context 'ctx', using buffer 'buf'.
 // 'buf' can be set to nil to use internal buffer.
 // 'ctx' is the user-defined value passed through the gaio watcher unchanged.
-func (w *Watcher) Read(ctx interface{}, conn net.Conn, buf []byte) error {
+// The returned RequestID can be passed to Cancel to abort the request before
+// it completes.
+func (w *Watcher) Read(ctx interface{}, conn net.Conn, buf []byte) (RequestID, error) {
 	return w.aioCreate(ctx, OpRead, conn, buf, zeroTime)
 }
 
 // ReadTimeout submits an async read request on 'fd' with context 'ctx', using buffer 'buf', and
 // expected to be completed before 'deadline'.
 // 'ctx' is the user-defined value passed through the gaio watcher unchanged.
-func (w *Watcher) ReadTimeout(ctx interface{}, conn net.Conn, buf []byte, deadline time.Time) error {
+func (w *Watcher) ReadTimeout(ctx interface{}, conn net.Conn, buf []byte, deadline time.Time) (RequestID, error) {
 	return w.aioCreate(ctx, OpRead, conn, buf, deadline)
 }
 
 // Write submits an async write request on 'fd' with context 'ctx', using buffer 'buf'.
 // 'ctx' is the user-defined value passed through the gaio watcher unchanged.
-func (w *Watcher) Write(ctx interface{}, conn net.Conn, buf []byte) error {
+func (w *Watcher) Write(ctx interface{}, conn net.Conn, buf []byte) (RequestID, error) {
 	if len(buf) == 0 {
-		return ErrEmptyBuffer
+		return 0, ErrEmptyBuffer
 	}
 	return w.aioCreate(ctx, OpWrite, conn, buf, zeroTime)
 }
@@ -215,42 +352,148 @@ func (w *Watcher) Write(ctx interface{}, conn net.Conn, buf []byte) error {
 // WriteTimeout submits an async write request on 'fd' with context 'ctx', using buffer 'buf', and
 // expected to be completed before 'deadline', 'buf' can be set to nil to use internal buffer.
 // 'ctx' is the user-defined value passed through the gaio watcher unchanged.
-func (w *Watcher) WriteTimeout(ctx interface{}, conn net.Conn, buf []byte, deadline time.Time) error {
+func (w *Watcher) WriteTimeout(ctx interface{}, conn net.Conn, buf []byte, deadline time.Time) (RequestID, error) {
 	if len(buf) == 0 {
-		return ErrEmptyBuffer
+		return 0, ErrEmptyBuffer
 	}
 	return w.aioCreate(ctx, OpWrite, conn, buf, deadline)
 }
 
+// Readv submits an async vectored read request on 'fd' with context 'ctx',
+// scattering the received bytes across 'buffers' as readv(2) would.
+// 'ctx' is the user-defined value passed through the gaio watcher unchanged.
+func (w *Watcher) Readv(ctx interface{}, conn net.Conn, buffers [][]byte, deadline time.Time) (RequestID, error) {
+	if len(buffers) == 0 {
+		return 0, ErrEmptyBuffer
+	}
+	return w.aioCreateV(ctx, OpRead, conn, buffers, deadline)
+}
+
+// Writev submits an async vectored write request on 'fd' with context 'ctx',
+// gathering 'buffers' as writev(2) would, avoiding a copy into a single
+// contiguous buffer when framing length-prefixed messages or similar.
+// 'ctx' is the user-defined value passed through the gaio watcher unchanged.
+func (w *Watcher) Writev(ctx interface{}, conn net.Conn, buffers [][]byte, deadline time.Time) (RequestID, error) {
+	if len(buffers) == 0 {
+		return 0, ErrEmptyBuffer
+	}
+	return w.aioCreateV(ctx, OpWrite, conn, buffers, deadline)
+}
+
 // Free let the watcher to release resources related to this conn immediately,
 // like socket file descriptors.
 func (w *Watcher) Free(conn net.Conn) error {
-	return w.aioCreate(nil, opDelete, conn, nil, zeroTime)
+	_, err := w.aioCreate(nil, opDelete, conn, nil, zeroTime)
+	return err
 }
 
-// core async-io creation
-func (w *Watcher) aioCreate(ctx interface{}, op OpType, conn net.Conn, buf []byte, deadline time.Time) error {
+// Cancel aborts a still-pending Read/Write/ReadTimeout/WriteTimeout request
+// identified by 'id'. If the request has already completed (or never
+// existed), ErrRequestNotFound is returned. On success the original request
+// is delivered through WaitIO as an OpResult with Error set to ErrCanceled.
+func (w *Watcher) Cancel(id RequestID) error {
+	req := cancelRequest{id: id, result: make(chan error, 1)}
+	select {
+	case w.chCancel <- req:
+	case <-w.die:
+		return ErrWatcherClosed
+	}
+
 	select {
+	case err := <-req.result:
+		return err
 	case <-w.die:
 		return ErrWatcherClosed
+	}
+}
+
+// core async-io creation
+func (w *Watcher) aioCreate(ctx interface{}, op OpType, conn net.Conn, buf []byte, deadline time.Time) (RequestID, error) {
+	select {
+	case <-w.die:
+		return 0, ErrWatcherClosed
 	default:
 		var ptr uintptr
 		if reflect.TypeOf(conn).Kind() == reflect.Ptr {
 			ptr = reflect.ValueOf(conn).Pointer()
 		} else {
-			return ErrUnsupported
+			return 0, ErrUnsupported
 		}
-		w.pendingMutex.Lock()
-		w.pending = append(w.pending, &aiocb{op: op, ptr: ptr, ctx: ctx, conn: conn, buffer: buf, deadline: deadline})
-		w.pendingMutex.Unlock()
+
+		id := RequestID(atomic.AddUint64(&w.nextID, 1))
+		cb := w.getCB()
+		cb.id = id
+		cb.op = op
+		cb.ptr = ptr
+		cb.ctx = ctx
+		cb.conn = conn
+		cb.buffer = buf
+		cb.deadline = deadline
+		w.pendingQ.Push(cb)
+
+		w.notifyPending()
+		w.trace(OpSubmitted, 0, ctx)
+		return id, nil
+	}
+}
+
+// core vectored async-io creation, mirrors aioCreate but for Readv/Writev
+func (w *Watcher) aioCreateV(ctx interface{}, op OpType, conn net.Conn, buffers [][]byte, deadline time.Time) (RequestID, error) {
+	select {
+	case <-w.die:
+		return 0, ErrWatcherClosed
+	default:
+		var ptr uintptr
+		if reflect.TypeOf(conn).Kind() == reflect.Ptr {
+			ptr = reflect.ValueOf(conn).Pointer()
+		} else {
+			return 0, ErrUnsupported
+		}
+
+		id := RequestID(atomic.AddUint64(&w.nextID, 1))
+		cb := w.getCB()
+		cb.id = id
+		cb.op = op
+		cb.ptr = ptr
+		cb.ctx = ctx
+		cb.conn = conn
+		cb.buffers = buffers
+		cb.deadline = deadline
+		w.pendingQ.Push(cb)
 
 		w.notifyPending()
-		return nil
+		w.trace(OpSubmitted, 0, ctx)
+		return id, nil
 	}
 }
 
 // tryRead will try to read data on aiocb and notify
 func (w *Watcher) tryRead(fd int, pcb *aiocb) bool {
+	w.trace(OpPolled, fd, pcb.ctx)
+
+	completed := w.tryReadDispatch(fd, pcb)
+	if completed {
+		w.trace(OpCompleted, fd, pcb.ctx)
+	}
+	return completed
+}
+
+func (w *Watcher) tryReadDispatch(fd int, pcb *aiocb) bool {
+	if pcb.pollConn != nil {
+		// PollableConn has no vectored primitive; fail the request
+		// explicitly instead of silently reading into a scratch buffer
+		// while leaving the caller's Buffers untouched.
+		if pcb.buffers != nil {
+			pcb.err = ErrPollableNoVector
+			return true
+		}
+		return tryReadPollable(pcb)
+	}
+
+	if pcb.buffers != nil {
+		return tryReadv(fd, pcb)
+	}
+
 	buf := pcb.buffer
 
 	var useSwap bool
@@ -261,7 +504,7 @@ func (w *Watcher) tryRead(fd int, pcb *aiocb) bool {
 
 	for {
 		// return values are stored in pcb
-		pcb.size, pcb.err = syscall.Read(fd, buf)
+		pcb.size, pcb.err = sysRead(fd, buf)
 		if pcb.err == syscall.EAGAIN {
 			return false
 		}
@@ -279,17 +522,43 @@ func (w *Watcher) tryRead(fd int, pcb *aiocb) bool {
 		pcb.buffer = buf
 		pcb.useSwap = true
 		w.nextSwapBuffer = (w.nextSwapBuffer + 1) % len(w.swapBuffer)
+		w.trace(SwapBufferRotated, fd, pcb.ctx)
 	}
 
 	return true
 }
 
 func (w *Watcher) tryWrite(fd int, pcb *aiocb) bool {
+	w.trace(OpPolled, fd, pcb.ctx)
+
+	completed := w.tryWriteDispatch(fd, pcb)
+	if completed {
+		w.trace(OpCompleted, fd, pcb.ctx)
+	}
+	return completed
+}
+
+func (w *Watcher) tryWriteDispatch(fd int, pcb *aiocb) bool {
+	if pcb.pollConn != nil {
+		// PollableConn has no vectored primitive; fail the request
+		// explicitly instead of silently writing from a scratch buffer
+		// while leaving the caller's Buffers untouched.
+		if pcb.buffers != nil {
+			pcb.err = ErrPollableNoVector
+			return true
+		}
+		return tryWritePollable(pcb)
+	}
+
+	if pcb.buffers != nil {
+		return tryWritev(fd, pcb)
+	}
+
 	var nw int
 	var ew error
 
 	if pcb.buffer != nil {
-		nw, ew = syscall.Write(fd, pcb.buffer[pcb.size:])
+		nw, ew = sysWrite(fd, pcb.buffer[pcb.size:])
 		pcb.err = ew
 		if ew == syscall.EAGAIN {
 			return false
@@ -309,6 +578,107 @@ func (w *Watcher) tryWrite(fd int, pcb *aiocb) bool {
 	return false
 }
 
+// tryReadv performs one vectored read into pcb.buffers, advancing past any
+// fully-consumed elements. Like tryRead, a single successful Readv completes
+// the request regardless of whether all buffers were filled.
+func tryReadv(fd int, pcb *aiocb) bool {
+	for {
+		pcb.size, pcb.err = sysReadv(fd, pcb.buffers)
+		if pcb.err == syscall.EAGAIN {
+			return false
+		}
+		if pcb.err == syscall.EINTR {
+			continue
+		}
+		break
+	}
+
+	if pcb.err == nil {
+		advanceIovec(&pcb.buffers, pcb.size)
+	}
+	return true
+}
+
+// tryWritev performs one vectored write of pcb.buffers, advancing past any
+// fully-consumed elements. Unlike tryReadv, a write only completes once
+// every buffer has been drained or an error occurs.
+func tryWritev(fd int, pcb *aiocb) bool {
+	if len(pcb.buffers) == 0 {
+		return true
+	}
+
+	nw, ew := sysWritev(fd, pcb.buffers)
+	pcb.err = ew
+	if ew == syscall.EAGAIN {
+		return false
+	}
+
+	if ew == nil {
+		pcb.size += nw
+		advanceIovec(&pcb.buffers, nw)
+	}
+
+	if len(pcb.buffers) == 0 || ew != nil {
+		return true
+	}
+	return false
+}
+
+// tryReadPollable reads from a PollableConn in place of syscall.Read,
+// treating ErrWouldBlock the same way the syscall path treats EAGAIN.
+func tryReadPollable(pcb *aiocb) bool {
+	buf := pcb.buffer
+	if buf == nil {
+		buf = make([]byte, defaultPollableBufferSize)
+	}
+
+	pcb.size, pcb.err = pcb.pollConn.Read(buf)
+	if pcb.err == ErrWouldBlock {
+		return false
+	}
+
+	if pcb.buffer == nil {
+		pcb.buffer = buf
+		pcb.useSwap = true
+	}
+	return true
+}
+
+// tryWritePollable writes to a PollableConn in place of syscall.Write,
+// treating ErrWouldBlock the same way the syscall path treats EAGAIN.
+func tryWritePollable(pcb *aiocb) bool {
+	nw, ew := pcb.pollConn.Write(pcb.buffer[pcb.size:])
+	pcb.err = ew
+	if ew == ErrWouldBlock {
+		return false
+	}
+
+	if ew == nil {
+		pcb.size += nw
+	}
+
+	if pcb.size == len(pcb.buffer) || ew != nil {
+		return true
+	}
+	return false
+}
+
+// advanceIovec drops fully-consumed leading buffers from *iov and slices the
+// partially-consumed one, leaving only the remaining unwritten/unfilled data.
+func advanceIovec(iov *[][]byte, n int) {
+	bufs := *iov
+	for n > 0 && len(bufs) > 0 {
+		if n < len(bufs[0]) {
+			bufs[0] = bufs[0][n:]
+			n = 0
+		} else {
+			n -= len(bufs[0])
+			bufs = bufs[1:]
+		}
+	}
+	*iov = bufs
+}
+
 // the core event loop of this watcher
 func (w *Watcher) loop() {
 	// all descriptors
@@ -317,33 +687,47 @@ func (w *Watcher) loop() {
 	connIdents := make(map[uintptr]int)
 	gc := make(chan uintptr)
 
-	// for timeout operations
-	// aiocb has non-zero deadline exists in timeouts & queue
-	// at same time or in neither of them
-	timer := time.NewTimer(0)
-	var timeouts timedHeap
+	// tracks every aiocb currently sitting in a desc's readers/writers list,
+	// so Cancel can locate and remove it in O(1)
+	reqIndex := make(map[RequestID]*aiocb)
+
+	// for timeout operations, a hierarchical timing wheel gives O(1)
+	// insertion/removal instead of the heap fixups this used to need
+	wheel := newTimingWheel(wheelTickDuration, wheelLevels, wheelSlotsPerLevel)
+	wheelTicker := time.NewTicker(wheelTickDuration)
+	defer wheelTicker.Stop()
 
 	releaseConn := func(ident int) {
 		if desc, ok := descs[ident]; ok {
-			// delete from heap
-			for e := desc.readers.Front(); e != nil; e = e.Next() {
-				tcb := e.Value.(*aiocb)
+			// delete from the wheel
+			for tcb := desc.readers.Front(); tcb != nil; tcb = desc.readers.Next(tcb) {
 				if !tcb.deadline.IsZero() {
-					heap.Remove(&timeouts, tcb.idx)
+					wheel.Remove(tcb)
 				}
+				delete(reqIndex, tcb.id)
 			}
 
-			for e := desc.writers.Front(); e != nil; e = e.Next() {
-				tcb := e.Value.(*aiocb)
+			for tcb := desc.writers.Front(); tcb != nil; tcb = desc.writers.Next(tcb) {
 				if !tcb.deadline.IsZero() {
-					heap.Remove(&timeouts, tcb.idx)
+					wheel.Remove(tcb)
 				}
+				delete(reqIndex, tcb.id)
 			}
 
 			delete(descs, ident)
 			delete(connIdents, desc.ptr)
-			// close socket file descriptor duplicated from net.Conn
-			syscall.Close(ident)
+			w.trace(ConnReleased, ident, nil)
+			if desc.pollable != nil {
+				// stop the goroutine-backed pseudo-poller driving it
+				close(desc.pollStop)
+			} else {
+				// drop the poller's registration before closing, so a
+				// future fd reuse of the same value can't still deliver
+				// readiness events tied to this, now-stale, registration
+				w.pfd.Free(ident)
+				// close socket file descriptor duplicated from net.Conn
+				sysClose(ident)
+			}
 		}
 	}
 
@@ -354,124 +738,165 @@ func (w *Watcher) loop() {
 		}
 	}()
 
-	var pending []*aiocb
-	for {
-		select {
-		case <-w.chPendingNotify:
-			// copy from w.pending to local pending
-			w.pendingMutex.Lock()
-			if cap(pending) < cap(w.pending) {
-				pending = make([]*aiocb, 0, cap(w.pending))
+	// negative, decrementing idents for PollableConn registrations, kept
+	// disjoint from real (non-negative) fds
+	nextPseudoIdent := -1
+
+	// drainPending dequeues every request currently sitting in pendingQ,
+	// binding it to a conn/fdDesc and either completing it inline or
+	// queuing it onto the conn's readers/writers list (and reqIndex).
+	// It's also invoked from the chCancel case below: aioCreate returns a
+	// RequestID to the caller as soon as it has pushed onto pendingQ, so a
+	// Cancel racing a just-submitted Read/Write can otherwise reach
+	// chCancel before chPendingNotify has been drained, and find nothing
+	// in reqIndex for a request that is neither completed nor unknown.
+	// Returns true if the watcher died while draining, in which case the
+	// caller must stop the loop.
+	drainPending := func() (dead bool) {
+		for {
+			pcb := w.pendingQ.Pop()
+			if pcb == nil {
+				return false
+			}
+			ident, ok := connIdents[pcb.ptr]
+			// resource release
+			if pcb.op == opDelete && ok {
+				releaseConn(ident)
+				w.putCB(pcb)
+				continue
 			}
-			pending = pending[:len(w.pending)]
-			copy(pending, w.pending)
-			w.pending = w.pending[:0]
-			w.pendingMutex.Unlock()
-
-			for _, pcb := range pending {
-				ident, ok := connIdents[pcb.ptr]
-				// resource release
-				if pcb.op == opDelete && ok {
-					releaseConn(ident)
-					continue
-				}
 
-				// new conn
-				var desc *fdDesc
-				if ok {
-					desc = descs[ident]
-				} else {
-					if dupfd, err := dupconn(pcb.conn); err != nil {
+			// new conn
+			var desc *fdDesc
+			if ok {
+				desc = descs[ident]
+			} else {
+				if dupfd, err := dupconn(pcb.conn); err != nil {
+					pc, pollable := pcb.conn.(PollableConn)
+					if !pollable {
 						select {
-						case w.chNotifyCompletion <- []OpResult{{Operation: pcb.op, Conn: pcb.conn, Buffer: pcb.buffer, Size: 0, Error: err, Context: pcb.ctx}}:
+						case w.chNotifyCompletion <- []OpResult{{Operation: pcb.op, Conn: pcb.conn, Buffer: pcb.buffer, Buffers: pcb.buffers, Size: 0, Error: err, Context: pcb.ctx}}:
 						case <-w.die:
-							return
+							return true
 						}
+						w.putCB(pcb)
 						continue
-					} else {
-						// assign idents
-						ident = dupfd
-
-						// unexpected situation, should notify caller
-						werr := w.pfd.Watch(ident)
-						if werr != nil {
-							select {
-							case w.chNotifyCompletion <- []OpResult{{Operation: pcb.op, Conn: pcb.conn, Buffer: pcb.buffer, Size: 0, Error: werr, Context: pcb.ctx}}:
-							case <-w.die:
-								return
-							}
-							continue
+					}
+
+					// no SyscallConn fast path available: drive it
+					// through a goroutine-backed pseudo-poller instead
+					ident = nextPseudoIdent
+					nextPseudoIdent--
+
+					desc = &fdDesc{ptr: pcb.ptr, pollable: pc, pollStop: make(chan struct{}), readers: newCBQueue(), writers: newCBQueue()}
+					descs[ident] = desc
+					connIdents[pcb.ptr] = ident
+					w.trace(ConnRegistered, ident, pcb.ctx)
+					go w.pollPump(pc, ident, desc.pollStop)
+
+					// same GC safety net as the real-fd path below
+					runtime.SetFinalizer(pcb.conn, func(c net.Conn) {
+						select {
+						case gc <- reflect.ValueOf(c).Pointer():
+						case <-w.die:
 						}
+					})
+				} else {
+					// assign idents
+					ident = dupfd
 
-						// bindings
-						desc = &fdDesc{ptr: pcb.ptr}
-						descs[ident] = desc
-						connIdents[pcb.ptr] = ident
-						// as we duplicated succesfuly, we're safe to
-						// close the original connection
-						pcb.conn.Close()
-
-						// the conn is still useful for GC finalizer
-						// note finalizer function cannot hold reference to net.Conn
-						// if not it will never be GC-ed
-						runtime.SetFinalizer(pcb.conn, func(c net.Conn) {
-							select {
-							case gc <- reflect.ValueOf(c).Pointer():
-							case <-w.die:
-							}
-						})
+					// unexpected situation, should notify caller
+					werr := w.pfd.Watch(ident)
+					if werr != nil {
+						select {
+						case w.chNotifyCompletion <- []OpResult{{Operation: pcb.op, Conn: pcb.conn, Buffer: pcb.buffer, Buffers: pcb.buffers, Size: 0, Error: werr, Context: pcb.ctx}}:
+						case <-w.die:
+							return true
+						}
+						w.putCB(pcb)
+						continue
 					}
+
+					// bindings
+					desc = &fdDesc{ptr: pcb.ptr, readers: newCBQueue(), writers: newCBQueue()}
+					descs[ident] = desc
+					connIdents[pcb.ptr] = ident
+					w.trace(ConnRegistered, ident, pcb.ctx)
+					// as we duplicated succesfuly, we're safe to
+					// close the original connection
+					pcb.conn.Close()
+
+					// the conn is still useful for GC finalizer
+					// note finalizer function cannot hold reference to net.Conn
+					// if not it will never be GC-ed
+					runtime.SetFinalizer(pcb.conn, func(c net.Conn) {
+						select {
+						case gc <- reflect.ValueOf(c).Pointer():
+						case <-w.die:
+						}
+					})
 				}
+			}
 
-				// operations splitted into different buckets
-				switch pcb.op {
-				case OpRead:
-					if desc.readers.Len() == 0 && desc.status&fdRead > 0 {
-						if w.tryRead(ident, pcb) {
-							select {
-							case w.chNotifyCompletion <- []OpResult{{Operation: OpRead, Conn: pcb.conn, Buffer: pcb.buffer, Size: pcb.size, Error: pcb.err, Context: pcb.ctx}}:
-							case <-w.die:
-								return
-							}
-							if pcb.err != nil || (pcb.size == 0 && pcb.err == nil) {
-								releaseConn(ident)
-							}
-							continue
-						} else {
-							desc.status &^= fdRead
+			if desc.pollable != nil {
+				pcb.pollConn = desc.pollable
+			}
+
+			// operations splitted into different buckets
+			switch pcb.op {
+			case OpRead:
+				if desc.readers.Len() == 0 && desc.status&fdRead > 0 {
+					if w.tryRead(ident, pcb) {
+						select {
+						case w.chNotifyCompletion <- []OpResult{{Operation: OpRead, Conn: pcb.conn, Buffer: pcb.buffer, Buffers: pcb.buffers, Size: pcb.size, Error: pcb.err, Context: pcb.ctx}}:
+						case <-w.die:
+							return true
 						}
-					}
-					pcb.l = &desc.readers
-					pcb.elem = pcb.l.PushBack(pcb)
-				case OpWrite:
-					if desc.writers.Len() == 0 && desc.status&fdWrite > 0 {
-						if w.tryWrite(ident, pcb) {
-							select {
-							case w.chNotifyCompletion <- []OpResult{{Operation: OpWrite, Conn: pcb.conn, Buffer: pcb.buffer, Size: pcb.size, Error: pcb.err, Context: pcb.ctx}}:
-							case <-w.die:
-								return
-							}
-							if pcb.err != nil {
-								releaseConn(ident)
-							}
-							continue
-						} else {
-							desc.status &^= fdWrite
+						if pcb.err != nil || (pcb.size == 0 && pcb.err == nil) {
+							releaseConn(ident)
 						}
+						w.putCB(pcb)
+						continue
+					} else {
+						desc.status &^= fdRead
 					}
-					pcb.l = &desc.writers
-					pcb.elem = pcb.l.PushBack(pcb)
 				}
-
-				// timer
-				if !pcb.deadline.IsZero() {
-					heap.Push(&timeouts, pcb)
-					if timeouts.Len() == 1 {
-						timer.Reset(pcb.deadline.Sub(time.Now()))
+				desc.readers.PushBack(pcb)
+				reqIndex[pcb.id] = pcb
+			case OpWrite:
+				if desc.writers.Len() == 0 && desc.status&fdWrite > 0 {
+					if w.tryWrite(ident, pcb) {
+						select {
+						case w.chNotifyCompletion <- []OpResult{{Operation: OpWrite, Conn: pcb.conn, Buffer: pcb.buffer, Buffers: pcb.buffers, Size: pcb.size, Error: pcb.err, Context: pcb.ctx}}:
+						case <-w.die:
+							return true
+						}
+						if pcb.err != nil {
+							releaseConn(ident)
+						}
+						w.putCB(pcb)
+						continue
+					} else {
+						desc.status &^= fdWrite
 					}
 				}
+				desc.writers.PushBack(pcb)
+				reqIndex[pcb.id] = pcb
+			}
+
+			// timer
+			if !pcb.deadline.IsZero() {
+				wheel.Add(pcb, time.Now())
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-w.chPendingNotify:
+			if drainPending() {
+				return
 			}
-			pending = pending[:0]
 		case pe := <-w.chEventNotify:
 			// suppose fd(s) being polled is closed by conn.Close() from outside after chanrecv,
 			// and a new conn has re-opened with the same handler number(fd). The read and write
@@ -482,18 +907,18 @@ func (w *Watcher) loop() {
 			// identified by 'e.ident', all library operation will be based on 'e.ident',
 			// then IO operation is impossible to misread or miswrite on re-created fd.
 			//log.Println(e)
+			w.trace(PollerWake, len(pe), nil)
 			results := w.swapResults[w.swapIdx][:0]
 			for _, e := range pe {
 				if desc, ok := descs[e.ident]; ok {
 					var shouldRelease bool
 					if e.r {
 						desc.status |= fdRead
-						var next *list.Element
-						for elem := desc.readers.Front(); elem != nil; elem = next {
-							next = elem.Next()
-							pcb := elem.Value.(*aiocb)
+						var next *aiocb
+						for pcb := desc.readers.Front(); pcb != nil; pcb = next {
+							next = desc.readers.Next(pcb)
 							if w.tryRead(e.ident, pcb) {
-								results = append(results, OpResult{Operation: OpRead, Conn: pcb.conn, Buffer: pcb.buffer, Size: pcb.size, Error: pcb.err, Context: pcb.ctx})
+								results = append(results, OpResult{Operation: OpRead, Conn: pcb.conn, Buffer: pcb.buffer, Buffers: pcb.buffers, Size: pcb.size, Error: pcb.err, Context: pcb.ctx})
 								// for shared memory, we need to notify WaitIO immediately
 								if pcb.useSwap {
 									select {
@@ -504,11 +929,14 @@ func (w *Watcher) loop() {
 										return
 									}
 								}
-								desc.readers.Remove(elem)
+								desc.readers.Remove(pcb)
+								delete(reqIndex, pcb.id)
 								if !pcb.deadline.IsZero() {
-									heap.Remove(&timeouts, pcb.idx)
+									wheel.Remove(pcb)
 								}
-								if pcb.err != nil || (pcb.size == 0 && pcb.err == nil) {
+								release := pcb.err != nil || (pcb.size == 0 && pcb.err == nil)
+								w.putCB(pcb)
+								if release {
 									shouldRelease = true
 									break
 								}
@@ -521,17 +949,19 @@ func (w *Watcher) loop() {
 
 					if e.w {
 						desc.status |= fdWrite
-						var next *list.Element
-						for elem := desc.writers.Front(); elem != nil; elem = next {
-							next = elem.Next()
-							pcb := elem.Value.(*aiocb)
+						var next *aiocb
+						for pcb := desc.writers.Front(); pcb != nil; pcb = next {
+							next = desc.writers.Next(pcb)
 							if w.tryWrite(e.ident, pcb) {
-								results = append(results, OpResult{Operation: OpWrite, Conn: pcb.conn, Buffer: pcb.buffer, Size: pcb.size, Error: pcb.err, Context: pcb.ctx})
-								desc.writers.Remove(elem)
+								results = append(results, OpResult{Operation: OpWrite, Conn: pcb.conn, Buffer: pcb.buffer, Buffers: pcb.buffers, Size: pcb.size, Error: pcb.err, Context: pcb.ctx})
+								desc.writers.Remove(pcb)
+								delete(reqIndex, pcb.id)
 								if !pcb.deadline.IsZero() {
-									heap.Remove(&timeouts, pcb.idx)
+									wheel.Remove(pcb)
 								}
-								if pcb.err != nil {
+								release := pcb.err != nil
+								w.putCB(pcb)
+								if release {
 									shouldRelease = true
 									break
 								}
@@ -558,25 +988,57 @@ func (w *Watcher) loop() {
 				}
 			}
 
-		case <-timer.C:
-			for timeouts.Len() > 0 {
-				now := time.Now()
-				pcb := timeouts[0]
-				if now.After(pcb.deadline) {
-					// remove from list
-					pcb.l.Remove(pcb.elem)
-					// ErrDeadline
-					select {
-					case w.chNotifyCompletion <- []OpResult{{Operation: pcb.op, Conn: pcb.conn, Buffer: pcb.buffer, Size: pcb.size, Error: ErrDeadline, Context: pcb.ctx}}:
-					case <-w.die:
-						return
-					}
-					heap.Pop(&timeouts)
-				} else {
-					timer.Reset(pcb.deadline.Sub(now))
-					break
+		case <-wheelTicker.C:
+			for _, pcb := range wheel.Advance() {
+				// remove from its desc's reader/writer queue
+				pcb.qHome.Remove(pcb)
+				delete(reqIndex, pcb.id)
+				w.trace(DeadlineFired, 0, pcb.ctx)
+				// ErrDeadline
+				select {
+				case w.chNotifyCompletion <- []OpResult{{Operation: pcb.op, Conn: pcb.conn, Buffer: pcb.buffer, Buffers: pcb.buffers, Size: pcb.size, Error: ErrDeadline, Context: pcb.ctx}}:
+				case <-w.die:
+					return
+				}
+				w.putCB(pcb)
+			}
+		case creq := <-w.chCancel:
+			pcb, ok := reqIndex[creq.id]
+			if !ok {
+				// the request may still be sitting in pendingQ, submitted
+				// but not yet dequeued by this loop; drain it before
+				// concluding it's genuinely completed or unknown
+				if drainPending() {
+					return
 				}
+				pcb, ok = reqIndex[creq.id]
+			}
+			if !ok {
+				creq.result <- ErrRequestNotFound
+				continue
+			}
+
+			delete(reqIndex, creq.id)
+			pcb.qHome.Remove(pcb)
+			if !pcb.deadline.IsZero() {
+				wheel.Remove(pcb)
+			}
+
+			// reply to Cancel() before pushing the completion: Cancel and
+			// WaitIO are two separate calls from the caller's perspective,
+			// and chNotifyCompletion is unbuffered, so blocking here first
+			// would deadlock a caller who (as the doc comment instructs)
+			// calls Cancel() and only then calls WaitIO() to observe the
+			// result -- they'd never reach the WaitIO that would unblock
+			// this send.
+			creq.result <- nil
+
+			select {
+			case w.chNotifyCompletion <- []OpResult{{Operation: pcb.op, Conn: pcb.conn, Buffer: pcb.buffer, Buffers: pcb.buffers, Size: pcb.size, Error: ErrCanceled, Context: pcb.ctx}}:
+			case <-w.die:
+				return
 			}
+			w.putCB(pcb)
 		case ptr := <-gc: // gc recycled net.Conn
 			if ident, ok := connIdents[ptr]; ok {
 				// since it's gc-ed, queue is impossible to hold net.Conn
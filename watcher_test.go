@@ -0,0 +1,87 @@
+package gaio
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// tcpLoopback opens a connected pair of real, SyscallConn-backed TCP
+// sockets, the minimum a Watcher needs to exercise its syscall.Read/Write
+// fast path (net.Pipe()'s in-memory conns have no underlying fd).
+func tcpLoopback(t *testing.T) (a, b net.Conn) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	defer ln.Close()
+
+	acceptCh := make(chan net.Conn, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			errCh <- err
+			return
+		}
+		acceptCh <- conn
+	}()
+
+	dialed, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("net.Dial: %v", err)
+	}
+
+	select {
+	case accepted := <-acceptCh:
+		return dialed, accepted
+	case err := <-errCh:
+		t.Fatalf("ln.Accept: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Accept")
+	}
+	return nil, nil
+}
+
+// TestCancelAfterReadThenWaitIODoesNotDeadlock is a regression test for the
+// Cancel deadlock: Cancel's chCancel handler used to block sending the
+// ErrCanceled OpResult on the unbuffered chNotifyCompletion before replying
+// on creq.result, so a caller that (per Cancel's own doc comment) calls
+// Cancel() and then WaitIO() to observe the result would hang forever on
+// both ends, along with the entire watcher loop goroutine.
+func TestCancelAfterReadThenWaitIODoesNotDeadlock(t *testing.T) {
+	w, err := NewWatcherSize(65536)
+	if err != nil {
+		t.Fatalf("NewWatcherSize: %v", err)
+	}
+	defer w.Close()
+
+	a, b := tcpLoopback(t)
+	defer a.Close()
+	defer b.Close()
+
+	buf := make([]byte, 16)
+	id, err := w.Read(nil, a, buf)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := w.Cancel(id); err != nil {
+			t.Errorf("Cancel: %v", err)
+			return
+		}
+		if _, err := w.WaitIO(); err != nil {
+			t.Errorf("WaitIO: %v", err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Cancel()+WaitIO() deadlocked")
+	}
+}